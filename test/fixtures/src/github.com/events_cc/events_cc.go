@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -29,6 +31,16 @@ var logger = shim.NewLogger("events_cc")
 type EventSender struct {
 }
 
+// event is the structured payload set on every evtsender.<type> event,
+// giving listeners enough to reconstruct ordering (Sequence) and correlate
+// a reconnect's replay against what was already delivered (CorrelationId).
+type event struct {
+	Sequence      int    `json:"sequence"`
+	Payload       string `json:"payload"`
+	Timestamp     string `json:"timestamp"`
+	CorrelationId string `json:"correlationId,omitempty"`
+}
+
 // Init function
 func (t *EventSender) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	logger.Info("*********** Init ***********")
@@ -48,15 +60,21 @@ func (t *EventSender) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return shim.Error("Unknown function call")
 	}
 
+	if len(args) == 0 {
+		return shim.Error("Invalid invoke function name. Expecting \"invoke\" \"bulkInvoke\" \"query\" \"clear\"")
+	}
+
 	if args[0] == "invoke" {
 		return t.invoke(stub)
+	} else if args[0] == "bulkInvoke" {
+		return t.bulkInvoke(stub)
 	} else if args[0] == "query" {
 		return t.query(stub)
 	} else if args[0] == "clear" {
 		return t.clear(stub)
 	}
 
-	return shim.Error("Invalid invoke function name. Expecting \"invoke\" \"query\"")
+	return shim.Error("Invalid invoke function name. Expecting \"invoke\" \"bulkInvoke\" \"query\" \"clear\"")
 }
 
 // Invoke function
@@ -64,28 +82,105 @@ func (t *EventSender) invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	logger.Info("########### invoke start ###########")
 
 	_ , args := stub.GetFunctionAndParameters()
-	if len(args) != 2 {
-		return shim.Error("Incorrect number of arguments. Expecting 2")
+	if len(args) != 3 && len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 3 or 4: invoke, type, payload, [correlationId]")
 	}
-	b, err := stub.GetState("num_events")
+	eventType := args[1]
+	payload := args[2]
+
+	num_events, err := t.nextNumEvents(stub)
 	if err != nil {
-		return  shim.Error("Failed to get state")
+		return shim.Error(err.Error())
 	}
-	num_events, _ := strconv.Atoi(string(b))
 
-	tosend := "Event " + string(b) + args[1]
-	eventName := "evtsender" + args[0]
+	evt := event{
+		Sequence:  num_events,
+		Payload:   payload,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if len(args) == 4 {
+		evt.CorrelationId = args[3]
+	}
+	eventName := "evtsender." + eventType
 
 	logger.Infof("########### invoke - num_events:%s\n", num_events)
-	logger.Infof("########### invoke - tosend:%s\n", tosend)
 	logger.Infof("########### invoke - eventName:%s\n", eventName)
 
-	err = stub.PutState("num_events", []byte(strconv.Itoa(num_events+1)))
+	return t.setEvents(stub, eventName, num_events, evt)
+}
+
+// bulkInvoke emits count logical events of type eventType, packaged as a
+// single JSON array under one SetEvent call, working around the peer's
+// one-event-per-transaction limit.
+func (t *EventSender) bulkInvoke(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### bulkInvoke start ###########")
+
+	_, args := stub.GetFunctionAndParameters()
+	if len(args) != 3 && len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 3 or 4: bulkInvoke, type, count, [correlationId]")
+	}
+	eventType := args[1]
+	count, err := strconv.Atoi(args[2])
+	if err != nil {
+		return shim.Error("3rd argument count must be a numeric string")
+	}
+	if count <= 0 {
+		return shim.Error("3rd argument count must be a positive integer")
+	}
+	var correlationId string
+	if len(args) == 4 {
+		correlationId = args[3]
+	}
+
+	num_events, err := t.nextNumEvents(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	events := make([]event, 0, count)
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	for i := 0; i < count; i++ {
+		events = append(events, event{
+			Sequence:      num_events + i,
+			Payload:       "Event " + strconv.Itoa(num_events+i),
+			Timestamp:     timestamp,
+			CorrelationId: correlationId,
+		})
+	}
+	eventName := "evtsender." + eventType
+
+	logger.Infof("########### bulkInvoke - num_events:%s\n", num_events)
+	logger.Infof("########### bulkInvoke - eventName:%s\n", eventName)
+
+	return t.setEvents(stub, eventName, num_events+count-1, events)
+}
+
+// nextNumEvents reads the current num_events counter so callers can stamp
+// each event with a monotonically increasing sequence number.
+func (t *EventSender) nextNumEvents(stub shim.ChaincodeStubInterface) (int, error) {
+	b, err := stub.GetState("num_events")
+	if err != nil {
+		return 0, err
+	}
+	num_events, _ := strconv.Atoi(string(b))
+	return num_events, nil
+}
+
+// setEvents persists the advanced num_events counter and fires a single
+// SetEvent carrying payload JSON-encoded: one event object for invoke, an
+// array of events for bulkInvoke.
+func (t *EventSender) setEvents(stub shim.ChaincodeStubInterface, eventName string, lastSequence int, payload interface{}) pb.Response {
+	err := stub.PutState("num_events", []byte(strconv.Itoa(lastSequence+1)))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	eventsJSONBytes, err := json.Marshal(payload)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
 
-	err = stub.SetEvent(eventName, []byte(tosend))
+	err = stub.SetEvent(eventName, eventsJSONBytes)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
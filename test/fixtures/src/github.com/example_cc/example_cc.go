@@ -20,6 +20,7 @@ package main
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -63,8 +64,15 @@ func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		// testRichQuery an entity from its state
 		return t.testRichQuery(stub, args)
 	}
-	//logger.Errorf("Unknown action, check the first argument, must be one of 'delete', 'query', or 'move'. But got: %v", args[0])
-	return shim.Error(fmt.Sprintf("Unknown action, check the first argument, must be one of 'delete', 'query', or 'move'. But got: %v", args[0]))
+	if function == "testHistoryQuery" {
+		// testHistoryQuery the full provenance of an entity
+		return t.testHistoryQuery(stub, args)
+	}
+	if function == "delete" {
+		// deletes an entity from its state
+		return t.delete(stub, args)
+	}
+	return shim.Error(fmt.Sprintf("Unknown function, must be one of 'query', 'add', 'testRichQuery', 'testHistoryQuery', or 'delete'. But got: %v", function))
 }
 
 func (t *SimpleChaincode) add(stub shim.ChaincodeStubInterface, args []string) pb.Response {
@@ -119,8 +127,10 @@ func (t *SimpleChaincode) testRichQuery(stub shim.ChaincodeStubInterface, args [
 }
 //历史数据查询GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error)
 func (t *SimpleChaincode) testHistoryQuery(stub shim.ChaincodeStubInterface, args []string) pb.Response{
-	student1:=Land{1,"Devin Zeng"}
-	key:="Student:"+strconv.Itoa(student1.Id)
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1: id")
+	}
+	key:="Land:"+args[0]
 	it,err:= stub.GetHistoryForKey(key)
 	if err!=nil{
 		return shim.Error(err.Error())
@@ -128,10 +138,32 @@ func (t *SimpleChaincode) testHistoryQuery(stub shim.ChaincodeStubInterface, arg
 	var result,_= getHistoryListResult(it)
 	return shim.Success(result)
 }
+
+// Deletes an entity from state, leaving a tombstone entry in its history.
+func (t *SimpleChaincode) delete(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1: id")
+	}
+	key:="Land:"+args[0]
+	if err := stub.DelState(key); err != nil {
+		return shim.Error("Failed to delete state")
+	}
+	return shim.Success(nil)
+}
+// HistoryEntry is the JSON shape returned for each record in a key's
+// provenance, with the protobuf Timestamp rendered as RFC3339 so callers
+// don't need to know the peer's internal representation.
+type HistoryEntry struct {
+	TxId      string `json:"TxId"`
+	Timestamp string `json:"Timestamp"`
+	IsDelete  bool   `json:"IsDelete"`
+	Value     string `json:"Value"`
+}
+
 func getHistoryListResult(resultsIterator shim.HistoryQueryIteratorInterface) ([]byte,error){
 
 	defer resultsIterator.Close()
-	// buffer is a JSON array containing QueryRecords
+	// buffer is a JSON array containing HistoryEntry records, oldest first
 	var buffer bytes.Buffer
 	buffer.WriteString("[")
 
@@ -145,7 +177,13 @@ func getHistoryListResult(resultsIterator shim.HistoryQueryIteratorInterface) ([
 		if bArrayMemberAlreadyWritten == true {
 			buffer.WriteString(",")
 		}
-		item,_:= json.Marshal( queryResponse)
+		entry := HistoryEntry{
+			TxId:      queryResponse.TxId,
+			Timestamp: time.Unix(queryResponse.Timestamp.Seconds, int64(queryResponse.Timestamp.Nanos)).UTC().Format(time.RFC3339),
+			IsDelete:  queryResponse.IsDelete,
+			Value:     string(queryResponse.Value),
+		}
+		item,_:= json.Marshal(entry)
 		buffer.Write(item)
 		bArrayMemberAlreadyWritten = true
 	}
@@ -0,0 +1,425 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+var logger = shim.NewLogger("example_cc_asset_exchange")
+
+// historyIndex composite keys the AssetHistory ledger by asset, transaction
+// type and finally the transaction id, so a single asset accumulates one
+// entry per enrollment/transfer and queryAssetHistory can range over either
+// a single type or the whole history.
+const historyIndex = "assetID-txType-txID"
+
+const (
+	txTypeEnrollment = "enrollment"
+	txTypeTransfer   = "transfer"
+	txTypeAll        = "all"
+)
+
+// User owns zero or more Assets, tracked here by id for fast lookup when
+// userDestroy needs to transfer or purge everything a user holds.
+type User struct {
+	Name   string   `json:"name"`
+	ID     string   `json:"id"`
+	Assets []string `json:"assets"`
+}
+
+// Asset is a single tradeable entity with free-form Metadata and a current
+// Owner, which is always the id of a User.
+type Asset struct {
+	Name     string            `json:"name"`
+	ID       string            `json:"id"`
+	Metadata map[string]string `json:"metadata"`
+	Owner    string            `json:"owner"`
+}
+
+// AssetHistory is one provenance entry for an Asset: either its original
+// enrollment or a later exchange between owners.
+type AssetHistory struct {
+	AssetID      string `json:"assetID"`
+	OriginOwner  string `json:"originOwner"`
+	CurrentOwner string `json:"currentOwner"`
+	TxType       string `json:"txType"`
+}
+
+// SimpleChaincode example simple Chaincode implementation
+type SimpleChaincode struct {
+}
+
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### example_cc_asset_exchange Init ###########")
+	return shim.Success(nil)
+}
+
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### example_cc_asset_exchange Invoke ###########")
+
+	function, args := stub.GetFunctionAndParameters()
+	switch function {
+	case "userRegister":
+		return t.userRegister(stub, args)
+	case "userDestroy":
+		return t.userDestroy(stub, args)
+	case "assetEnroll":
+		return t.assetEnroll(stub, args)
+	case "assetExchange":
+		return t.assetExchange(stub, args)
+	case "queryUser":
+		return t.queryUser(stub, args)
+	case "queryAsset":
+		return t.queryAsset(stub, args)
+	case "queryAssetHistory":
+		return t.queryAssetHistory(stub, args)
+	}
+	return shim.Error(fmt.Sprintf("Unknown function, must be one of 'userRegister', 'userDestroy', 'assetEnroll', 'assetExchange', 'queryUser', 'queryAsset', or 'queryAssetHistory'. But got: %v", function))
+}
+
+// userRegister creates a User with no assets.
+//
+// args: 0-id  1-name
+func (t *SimpleChaincode) userRegister(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: id, name")
+	}
+	id := args[0]
+	name := args[1]
+
+	user := User{name, id, []string{}}
+	userJSONBytes, err := json.Marshal(user)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(userKey(id), userJSONBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success([]byte("Registered user " + id))
+}
+
+// userDestroy removes a User, first transferring any owned assets to the
+// recipient named by args[1], or purging them outright when no recipient
+// is given.
+//
+// args: 0-id  1-recipientId (optional)
+func (t *SimpleChaincode) userDestroy(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 && len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 1 or 2: id, [recipientId]")
+	}
+	id := args[0]
+
+	user, err := t.loadUser(stub, id)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if user == nil {
+		return shim.Error("User not found: " + id)
+	}
+
+	if len(args) == 2 {
+		recipientId := args[1]
+		for _, assetID := range user.Assets {
+			if resp := t.doAssetExchange(stub, assetID, recipientId); resp.Status != shim.OK {
+				return resp
+			}
+		}
+	} else {
+		for _, assetID := range user.Assets {
+			if err := stub.DelState(assetKey(assetID)); err != nil {
+				return shim.Error("Failed to purge asset " + assetID + ": " + err.Error())
+			}
+		}
+	}
+
+	if err := stub.DelState(userKey(id)); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success([]byte("Destroyed user " + id))
+}
+
+// assetEnroll creates an Asset owned by ownerId and records the enrollment
+// in AssetHistory.
+//
+// args: 0-assetID  1-name  2-ownerId  3-metadataJSON
+func (t *SimpleChaincode) assetEnroll(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 4: assetID, name, ownerId, metadataJSON")
+	}
+	assetID := args[0]
+	name := args[1]
+	ownerId := args[2]
+
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(args[3]), &metadata); err != nil {
+		return shim.Error("4th argument metadataJSON must be a JSON object: " + err.Error())
+	}
+
+	owner, err := t.loadUser(stub, ownerId)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if owner == nil {
+		return shim.Error("User not found: " + ownerId)
+	}
+
+	asset := Asset{name, assetID, metadata, ownerId}
+	assetJSONBytes, err := json.Marshal(asset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(assetKey(assetID), assetJSONBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	owner.Assets = append(owner.Assets, assetID)
+	if err := t.saveUser(stub, owner); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := t.recordHistory(stub, AssetHistory{assetID, ownerId, ownerId, txTypeEnrollment}); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte("Enrolled asset " + assetID))
+}
+
+// assetExchange moves an Asset to a new owner and records the transfer in
+// AssetHistory.
+//
+// args: 0-assetID  1-newOwnerId
+func (t *SimpleChaincode) assetExchange(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: assetID, newOwnerId")
+	}
+	return t.doAssetExchange(stub, args[0], args[1])
+}
+
+func (t *SimpleChaincode) doAssetExchange(stub shim.ChaincodeStubInterface, assetID string, newOwnerId string) pb.Response {
+	asset, err := t.loadAsset(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if asset == nil {
+		return shim.Error("Asset not found: " + assetID)
+	}
+
+	newOwner, err := t.loadUser(stub, newOwnerId)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if newOwner == nil {
+		return shim.Error("User not found: " + newOwnerId)
+	}
+
+	oldOwnerId := asset.Owner
+	if oldOwnerId != newOwnerId {
+		oldOwner, err := t.loadUser(stub, oldOwnerId)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if oldOwner != nil {
+			oldOwner.Assets = removeAssetID(oldOwner.Assets, assetID)
+			if err := t.saveUser(stub, oldOwner); err != nil {
+				return shim.Error(err.Error())
+			}
+		}
+
+		newOwner.Assets = append(newOwner.Assets, assetID)
+		if err := t.saveUser(stub, newOwner); err != nil {
+			return shim.Error(err.Error())
+		}
+
+		asset.Owner = newOwnerId
+		assetJSONBytes, err := json.Marshal(asset)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := stub.PutState(assetKey(assetID), assetJSONBytes); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+
+	if err := t.recordHistory(stub, AssetHistory{assetID, oldOwnerId, newOwnerId, txTypeTransfer}); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte("Exchanged asset " + assetID + " to " + newOwnerId))
+}
+
+// queryUser returns the User with the given id.
+//
+// args: 0-id
+func (t *SimpleChaincode) queryUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1: id")
+	}
+	userBytes, err := stub.GetState(userKey(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if userBytes == nil {
+		return shim.Error("User not found: " + args[0])
+	}
+	return shim.Success(userBytes)
+}
+
+// queryAsset returns the Asset with the given id.
+//
+// args: 0-id
+func (t *SimpleChaincode) queryAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1: id")
+	}
+	assetBytes, err := stub.GetState(assetKey(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if assetBytes == nil {
+		return shim.Error("Asset not found: " + args[0])
+	}
+	return shim.Success(assetBytes)
+}
+
+// queryAssetHistory returns the AssetHistory entries for assetID, filtered
+// by historyType ("enrollment", "transfer", or "all").
+//
+// args: 0-assetID  1-historyType
+func (t *SimpleChaincode) queryAssetHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: assetID, historyType")
+	}
+	assetID := args[0]
+	historyType := args[1]
+
+	attributes := []string{assetID}
+	switch historyType {
+	case txTypeEnrollment, txTypeTransfer:
+		attributes = append(attributes, historyType)
+	case txTypeAll:
+		// leave attributes as just assetID to scan every txType
+	default:
+		return shim.Error("2nd argument historyType must be one of 'enrollment', 'transfer', or 'all'. But got: " + historyType)
+	}
+
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(historyIndex, attributes)
+	if err != nil {
+		return shim.Error("History query failed: " + err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		buffer.Write(queryResponse.Value)
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("]")
+
+	fmt.Printf("queryResult:\n%s\n", buffer.String())
+	return shim.Success(buffer.Bytes())
+}
+
+func (t *SimpleChaincode) recordHistory(stub shim.ChaincodeStubInterface, history AssetHistory) error {
+	historyJSONBytes, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	key, err := stub.CreateCompositeKey(historyIndex, []string{history.AssetID, history.TxType, stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, historyJSONBytes)
+}
+
+func (t *SimpleChaincode) loadUser(stub shim.ChaincodeStubInterface, id string) (*User, error) {
+	userBytes, err := stub.GetState(userKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if userBytes == nil {
+		return nil, nil
+	}
+	var user User
+	if err := json.Unmarshal(userBytes, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (t *SimpleChaincode) saveUser(stub shim.ChaincodeStubInterface, user *User) error {
+	userJSONBytes, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(userKey(user.ID), userJSONBytes)
+}
+
+func (t *SimpleChaincode) loadAsset(stub shim.ChaincodeStubInterface, id string) (*Asset, error) {
+	assetBytes, err := stub.GetState(assetKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if assetBytes == nil {
+		return nil, nil
+	}
+	var asset Asset
+	if err := json.Unmarshal(assetBytes, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func userKey(id string) string {
+	return "User:" + id
+}
+
+func assetKey(id string) string {
+	return "Asset:" + id
+}
+
+func removeAssetID(assets []string, assetID string) []string {
+	result := make([]string, 0, len(assets))
+	for _, a := range assets {
+		if a != assetID {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func main() {
+	err := shim.Start(new(SimpleChaincode))
+	if err != nil {
+		logger.Errorf("Error starting Simple chaincode: %s", err)
+	}
+}
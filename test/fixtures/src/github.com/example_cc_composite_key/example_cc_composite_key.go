@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+var logger = shim.NewLogger("example_cc_composite_key")
+
+const indexName = "id-owner-dc"
+
+// Item is the entity enrolled by this fixture. Its state is addressed by
+// its own id, and indexed a second time via a composite key so it can be
+// found by owner without a rich-query capable state database.
+type Item struct {
+	Id    string `json:"id"`
+	Owner string `json:"owner"`
+	Color string `json:"color"`
+}
+
+// SimpleChaincode example simple Chaincode implementation
+type SimpleChaincode struct {
+}
+
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### test Init ###########")
+	return shim.Success(nil)
+}
+
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### test Invoke ###########")
+
+	function, args := stub.GetFunctionAndParameters()
+	if function == "enroll" {
+		return t.enroll(stub, args)
+	}
+	if function == "transfer" {
+		return t.transfer(stub, args)
+	}
+	if function == "queryByOwner" {
+		return t.queryByOwner(stub, args)
+	}
+	if function == "queryByOwnerAndColor" {
+		return t.queryByOwnerAndColor(stub, args)
+	}
+	return shim.Error(fmt.Sprintf("Unknown function, must be one of 'enroll', 'transfer', 'queryByOwner', or 'queryByOwnerAndColor'. But got: %v", function))
+}
+
+// enroll creates an Item and its id-owner-dc composite-key index entry.
+//
+// args: 0-id  1-owner  2-color
+func (t *SimpleChaincode) enroll(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 3: id, owner, color")
+	}
+	id := args[0]
+	owner := args[1]
+	color := args[2]
+
+	item := Item{id, owner, color}
+	itemJSONBytes, err := json.Marshal(item)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(id, itemJSONBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := t.putCompositeKey(stub, id, owner, color); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte("Enrolled item " + id))
+}
+
+// transfer moves an Item to a new owner, deleting the old composite-key
+// index entry and writing a new one so queryByOwner stays accurate.
+//
+// args: 0-id  1-newOwner
+func (t *SimpleChaincode) transfer(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: id, newOwner")
+	}
+	id := args[0]
+	newOwner := args[1]
+
+	itemBytes, err := stub.GetState(id)
+	if err != nil {
+		return shim.Error("Failed to get state")
+	}
+	if itemBytes == nil {
+		return shim.Error("Item not found: " + id)
+	}
+	var item Item
+	if err := json.Unmarshal(itemBytes, &item); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	oldKey, err := stub.CreateCompositeKey(indexName, []string{item.Id, item.Owner, item.Color})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.DelState(oldKey); err != nil {
+		return shim.Error("Failed to delete old composite key: " + err.Error())
+	}
+
+	item.Owner = newOwner
+	itemJSONBytes, err := json.Marshal(item)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(id, itemJSONBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := t.putCompositeKey(stub, item.Id, item.Owner, item.Color); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte("Transferred item " + id + " to " + newOwner))
+}
+
+func (t *SimpleChaincode) putCompositeKey(stub shim.ChaincodeStubInterface, id string, owner string, color string) error {
+	key, err := stub.CreateCompositeKey(indexName, []string{id, owner, color})
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, []byte{0x00})
+}
+
+// queryByOwner finds every item belonging to owner by scanning the
+// id-owner-dc index and keeping the entries whose owner attribute matches.
+//
+// args: 0-owner
+func (t *SimpleChaincode) queryByOwner(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1: owner")
+	}
+	return t.queryByPartialCompositeKey(stub, []string{args[0]})
+}
+
+// queryByOwnerAndColor narrows queryByOwner down to a single color.
+//
+// args: 0-owner  1-color
+func (t *SimpleChaincode) queryByOwnerAndColor(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: owner, color")
+	}
+	return t.queryByPartialCompositeKey(stub, []string{args[0], args[1]})
+}
+
+func (t *SimpleChaincode) queryByPartialCompositeKey(stub shim.ChaincodeStubInterface, attributes []string) pb.Response {
+	// the index leads with id, so owner (and color) cannot be used as a key
+	// prefix; they are matched against the split key parts below instead
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(indexName, []string{})
+	if err != nil {
+		return shim.Error("Partial composite key query failed: " + err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		_, keyParts, err := stub.SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if len(keyParts) != 3 {
+			return shim.Error("Unexpected composite key shape: " + queryResponse.Key)
+		}
+		matched := true
+		for i, attribute := range attributes {
+			if keyParts[i+1] != attribute {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		item := Item{keyParts[0], keyParts[1], keyParts[2]}
+		itemJSONBytes, err := json.Marshal(item)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		buffer.Write(itemJSONBytes)
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("]")
+
+	fmt.Printf("queryResult:\n%s\n", buffer.String())
+	return shim.Success(buffer.Bytes())
+}
+
+func main() {
+	err := shim.Start(new(SimpleChaincode))
+	if err != nil {
+		logger.Errorf("Error starting Simple chaincode: %s", err)
+	}
+}
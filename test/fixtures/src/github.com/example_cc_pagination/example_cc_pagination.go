@@ -0,0 +1,192 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"encoding/json"
+)
+
+var logger = shim.NewLogger("example_cc_pagination")
+
+// SimpleChaincode example simple Chaincode implementation, same entity shape
+// as example_cc but with the rich-query and range-query functions extended
+// to exercise the paginated peer APIs.
+type SimpleChaincode struct {
+}
+
+type Land struct {
+	Id   int
+	Name string
+}
+
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### test Init ###########")
+	_, args := stub.GetFunctionAndParameters()
+	logger.Info(args)
+	return shim.Success(nil)
+}
+
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	logger.Info("########### test Invoke ###########")
+
+	function, args := stub.GetFunctionAndParameters()
+	logger.Infof("args:", args)
+	logger.Infof("function:", function)
+	if function == "add" {
+		// add an entity from its state
+		return t.add(stub, args)
+	}
+	if function == "queryWithPagination" {
+		// rich query with pageSize/bookmark
+		return t.queryWithPagination(stub, args)
+	}
+	if function == "rangeWithPagination" {
+		// range query with pageSize/bookmark
+		return t.rangeWithPagination(stub, args)
+	}
+	return shim.Error(fmt.Sprintf("Unknown function, must be one of 'add', 'queryWithPagination', or 'rangeWithPagination'. But got: %v", function))
+}
+
+func (t *SimpleChaincode) add(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2: id, name")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return shim.Error("1st argument id must be a numeric string")
+	}
+	land := Land{id, args[1]}
+	key := "Land:" + args[0] //Key格式为 Land:{Id}
+	landJSONBytes, err := json.Marshal(land)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = stub.PutState(key, landJSONBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success([]byte("Saved Land!"))
+}
+
+// queryWithPagination runs a CouchDB rich query, limited to pageSize results
+// starting from bookmark, and returns the matches together with the
+// ResponseMetadata (fetched record count and the bookmark for the next page).
+//
+// args: 0-name  1-pageSize  2-bookmark
+func (t *SimpleChaincode) queryWithPagination(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 3: name, pageSize, bookmark")
+	}
+	name := args[0]
+	pageSize, err := strconv.Atoi(args[1])
+	if err != nil {
+		return shim.Error("2nd argument pageSize must be a numeric string")
+	}
+	bookmark := args[2]
+
+	queryString := fmt.Sprintf("{\"selector\":{\"Name\":\"%s\"}}", name)
+	logger.Infof("queryWithPagination queryString:", queryString)
+	resultsIterator, metadata, err := stub.GetQueryResultWithPagination(queryString, int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error("Rich query with pagination failed: " + err.Error())
+	}
+	defer resultsIterator.Close()
+
+	result, err := getPaginatedListResult(resultsIterator, metadata)
+	if err != nil {
+		return shim.Error("Rich query with pagination failed: " + err.Error())
+	}
+	return shim.Success(result)
+}
+
+// rangeWithPagination runs a key-range query over the Land: keyspace, limited
+// to pageSize results starting from bookmark.
+//
+// args: 0-startKey  1-endKey  2-pageSize  3-bookmark
+func (t *SimpleChaincode) rangeWithPagination(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 4: startKey, endKey, pageSize, bookmark")
+	}
+	startKey := args[0]
+	endKey := args[1]
+	pageSize, err := strconv.Atoi(args[2])
+	if err != nil {
+		return shim.Error("3rd argument pageSize must be a numeric string")
+	}
+	bookmark := args[3]
+
+	resultsIterator, metadata, err := stub.GetStateByRangeWithPagination(startKey, endKey, int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error("Range query with pagination failed: " + err.Error())
+	}
+	defer resultsIterator.Close()
+
+	result, err := getPaginatedListResult(resultsIterator, metadata)
+	if err != nil {
+		return shim.Error("Range query with pagination failed: " + err.Error())
+	}
+	return shim.Success(result)
+}
+
+// getPaginatedListResult wraps the iterator's records and the
+// ResponseMetadata into a single JSON payload:
+// {"records":[{"Key":...,"Record":...}, ...],"fetchedRecordsCount":N,"bookmark":"..."}
+func getPaginatedListResult(resultsIterator shim.StateQueryIteratorInterface, metadata *pb.QueryResponseMetadata) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{\"records\":[")
+
+	bArrayMemberAlreadyWritten := false
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if bArrayMemberAlreadyWritten == true {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("{\"Key\":")
+		buffer.WriteString("\"")
+		buffer.WriteString(queryResponse.Key)
+		buffer.WriteString("\"")
+
+		buffer.WriteString(", \"Record\":")
+		buffer.WriteString(string(queryResponse.Value))
+		buffer.WriteString("}")
+		bArrayMemberAlreadyWritten = true
+	}
+	buffer.WriteString("], \"fetchedRecordsCount\":")
+	buffer.WriteString(strconv.Itoa(int(metadata.GetFetchedRecordsCount())))
+	buffer.WriteString(", \"bookmark\":\"")
+	buffer.WriteString(metadata.GetBookmark())
+	buffer.WriteString("\"}")
+
+	fmt.Printf("queryResult:\n%s\n", buffer.String())
+	return buffer.Bytes(), nil
+}
+
+func main() {
+	err := shim.Start(new(SimpleChaincode))
+	if err != nil {
+		logger.Errorf("Error starting Simple chaincode: %s", err)
+	}
+}
@@ -215,14 +215,29 @@ func (t *SimpleChaincode) delete(stub shim.ChaincodeStubInterface, args []string
 	return shim.Success(nil)
 }
 
+// transientDetail is the JSON shape expected under the "detail" transient
+// map key: the non-secret name stays in args, everything else travels as
+// transient data so it never lands in the transaction args on the ledger.
+type transientDetail struct {
+	Color string `json:"color"`
+	Size  int    `json:"size"`
+	Owner string `json:"owner"`
+}
+
+// transientSensitive is the JSON shape expected under the "sensitive"
+// transient map key.
+type transientSensitive struct {
+	Price int `json:"price"`
+}
+
 // ===============================================
 // setPrivateData - set private data to collections detailCol and sensitiveCol
 // ===============================================
 func (t *SimpleChaincode) setPrivateData(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	//  0-name  1-color  2-size  3-owner  4-price
-	// "test",  "blue",  "35",   "bob",   "99"
-	if len(args) != 5 {
-		return shim.Error("Incorrect number of arguments. Expecting 5")
+	//  0-name
+	// "test"
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1")
 	}
 
 	// ==== Input sanitation ====
@@ -230,29 +245,40 @@ func (t *SimpleChaincode) setPrivateData(stub shim.ChaincodeStubInterface, args
 	if len(args[0]) == 0 {
 		return shim.Error("1st argument must be a non-empty string")
 	}
-	if len(args[1]) == 0 {
-		return shim.Error("2nd argument must be a non-empty string")
+	name := args[0]
+
+	transientMap, err := stub.GetTransient()
+	if err != nil {
+		return shim.Error("Failed to get transient: " + err.Error())
 	}
-	if len(args[2]) == 0 {
-		return shim.Error("3rd argument must be a non-empty string")
+
+	detailBytes, ok := transientMap["detail"]
+	if !ok {
+		return shim.Error("detail must be present in the transient map")
 	}
-	if len(args[3]) == 0 {
-		return shim.Error("4th argument must be a non-empty string")
+	var detailInput transientDetail
+	if err := json.Unmarshal(detailBytes, &detailInput); err != nil {
+		return shim.Error("detail transient value must be a JSON object: " + err.Error())
 	}
-	if len(args[4]) == 0 {
-		return shim.Error("5th argument must be a non-empty string")
+	if len(detailInput.Color) == 0 || detailInput.Size == 0 || len(detailInput.Owner) == 0 {
+		return shim.Error("detail must supply a non-empty color, size and owner")
 	}
-	name := args[0]
-	color := strings.ToLower(args[1])
-	owner := strings.ToLower(args[3])
-	size, err := strconv.Atoi(args[2])
-	if err != nil {
-		return shim.Error("3rd argument must be a numeric string")
+	color := strings.ToLower(detailInput.Color)
+	owner := strings.ToLower(detailInput.Owner)
+	size := detailInput.Size
+
+	sensitiveBytes, ok := transientMap["sensitive"]
+	if !ok {
+		return shim.Error("sensitive must be present in the transient map")
 	}
-	price, err := strconv.Atoi(args[4])
-	if err != nil {
-		return shim.Error("5th argument must be a numeric string")
+	var sensitiveInput transientSensitive
+	if err := json.Unmarshal(sensitiveBytes, &sensitiveInput); err != nil {
+		return shim.Error("sensitive transient value must be a JSON object: " + err.Error())
+	}
+	if sensitiveInput.Price == 0 {
+		return shim.Error("sensitive must supply a non-zero price")
 	}
+	price := sensitiveInput.Price
 
 	// ==== Check if name already exists ====
 	//detailAsBytes, err := stub.GetPrivateData("detailCol", name)